@@ -122,6 +122,97 @@ func (ak *AutoKey) Write() error {
 	return nil
 }
 
+// Plan implements expander.DryRunner, returning the group directory and
+// snippet file changes Write would make without performing them.
+func (ak *AutoKey) Plan() ([]expander.Change, error) {
+	ak.mu.RLock()
+	defer ak.mu.RUnlock()
+	var changes []expander.Change
+	for _, g := range ak.groups {
+		if !g.managed {
+			continue
+		}
+		c, err := ak.planGroup(g.group)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c...)
+	}
+	return changes, nil
+}
+
+// planGroup recursively plans the changes for group and its children.
+// Write never deletes stale on-disk entries, so neither does planGroup.
+func (ak *AutoKey) planGroup(group *expander.Group) ([]expander.Change, error) {
+	var changes []expander.Change
+	dir := path.Join(ak.dir, group.Path())
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		changes = append(changes, expander.Change{Type: expander.Create, Kind: "group", Path: group.Path()})
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, g := range group.Groups {
+		c, err := ak.planGroup(g)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c...)
+	}
+	for _, s := range group.Snippets {
+		c, err := ak.planSnippet(s)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			changes = append(changes, *c)
+		}
+	}
+	return changes, nil
+}
+
+// planSnippet plans the change for a single Snippet, or returns nil if it's
+// already up to date on disk.
+func (ak *AutoKey) planSnippet(snippet *expander.Snippet) (*expander.Change, error) {
+	sPath := path.Join(ak.dir, fmt.Sprintf("%v.%v", snippet.Path(), snippetExt))
+	mdPath, err := metadataPath(sPath)
+	if err != nil {
+		return nil, err
+	}
+	newSize := int64(len(snippet.Text))
+	fi, err := os.Stat(mdPath)
+	if os.IsNotExist(err) {
+		return &expander.Change{
+			Type:       expander.Create,
+			Kind:       "snippet",
+			Path:       snippet.Path(),
+			NewSize:    newSize,
+			NewModTime: snippet.ModTime,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !fi.ModTime().Before(snippet.ModTime) {
+		return nil, nil
+	}
+	var oldSize int64
+	var oldModTime time.Time
+	if sfi, err := os.Stat(sPath); err == nil {
+		oldSize = sfi.Size()
+		oldModTime = sfi.ModTime()
+	}
+	return &expander.Change{
+		Type:       expander.Update,
+		Kind:       "snippet",
+		Path:       snippet.Path(),
+		OldSize:    oldSize,
+		NewSize:    newSize,
+		OldModTime: oldModTime,
+		NewModTime: snippet.ModTime,
+	}, nil
+}
+
 // writeGroup recursively writes all children groups and snippets to disk.
 func (ak *AutoKey) writeGroup(group *expander.Group) error {
 	glog.Infof("Writing group %v", group)
@@ -0,0 +1,245 @@
+// Package espanso is an Expander for Espanso.
+package espanso
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hazaelsan/spandex/expander"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	if err := expander.Register("Espanso", newEspanso); err != nil {
+		log.Fatal(err)
+	}
+}
+
+const (
+	dirMode  os.FileMode = 0755
+	fileMode os.FileMode = 0644
+	matchExt             = ".yml"
+)
+
+// Command line flags.
+var (
+	espansoDir = flag.String("espanso_dir", path.Join(os.Getenv("HOME"), ".config/espanso/match"), "Espanso match directory")
+)
+
+type rawGroup struct {
+	group   *expander.Group
+	managed bool
+}
+
+// Espanso is a YAML-based Expander.
+type Espanso struct {
+	dir    string
+	groups map[string]rawGroup
+	mu     sync.RWMutex
+}
+
+func newEspanso() expander.Expander {
+	return &Espanso{
+		dir:    *espansoDir,
+		groups: make(map[string]rawGroup),
+	}
+}
+
+// matchFile is the on-disk representation of an Espanso match package.
+type matchFile struct {
+	Matches []match `yaml:"matches"`
+}
+
+// match is a single Espanso trigger/replacement entry. Espanso also
+// supports a "vars" field for dynamic replacements (date, clipboard,
+// script, ...); expander.Snippet has no equivalent, so vars are not
+// preserved across Load/Write, the same loss AutoKey already accepts for
+// metadata fields like hotkeys or filters that don't fit the generic model.
+type match struct {
+	Trigger string `yaml:"trigger"`
+	Replace string `yaml:"replace,omitempty"`
+	Label   string `yaml:"label,omitempty"`
+}
+
+// Load initializes all settings from disk.
+func (e *Espanso) Load() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	files, err := ioutil.ReadDir(e.dir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range files {
+		if fi.IsDir() || filepath.Ext(fi.Name()) != matchExt {
+			continue
+		}
+		g, err := loadGroup(path.Join(e.dir, fi.Name()))
+		if err != nil {
+			return err
+		}
+		e.groups[g.Name] = rawGroup{group: g}
+	}
+	return nil
+}
+
+// Groups returns all children group entries.
+func (e *Espanso) Groups() []*expander.Group {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	var groups []*expander.Group
+	for _, g := range e.groups {
+		groups = append(groups, g.group)
+	}
+	return groups
+}
+
+// Group returns the child group of the given name.
+func (e *Espanso) Group(name string) *expander.Group {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, g := range e.groups {
+		if name == g.group.Name {
+			return g.group
+		}
+	}
+	return nil
+}
+
+// SetGroup upserts the given group.
+func (e *Espanso) SetGroup(group *expander.Group) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	g := rawGroup{
+		group:   group,
+		managed: true,
+	}
+	e.groups[g.group.Name] = g
+}
+
+// Write writes all managed groups to disk, one match file per flattened
+// group, since Espanso has no concept of nested groups.
+func (e *Espanso) Write() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := os.MkdirAll(e.dir, dirMode); err != nil {
+		return err
+	}
+	for _, g := range e.groups {
+		if !g.managed {
+			continue
+		}
+		for _, fg := range flatten(g.group, "") {
+			if err := e.writeGroup(fg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// flatGroup pairs a flattened (ancestor-joined) name with its source Group.
+type flatGroup struct {
+	name  string
+	group *expander.Group
+}
+
+// flatten recursively flattens a Group tree into Espanso's flat match-file
+// model, joining ancestor names since Espanso has no nested groups.
+func flatten(g *expander.Group, prefix string) []flatGroup {
+	name := g.Name
+	if prefix != "" {
+		name = path.Join(prefix, g.Name)
+	}
+	out := []flatGroup{{name: name, group: g}}
+	for _, child := range g.Groups {
+		out = append(out, flatten(child, name)...)
+	}
+	return out
+}
+
+// writeGroup writes a single flattened group to its match file, skipping
+// the write if the file is already at least as new as the group's
+// snippets.
+func (e *Espanso) writeGroup(fg flatGroup) error {
+	modTime := groupModTime(fg.group)
+	file := matchPath(e.dir, fg.name)
+	if fi, err := os.Stat(file); err == nil {
+		if !fi.ModTime().Before(modTime) {
+			return nil
+		}
+	}
+	mf := &matchFile{}
+	for _, s := range fg.group.Snippets {
+		mf.Matches = append(mf.Matches, match{
+			Trigger: s.Abbr,
+			Replace: s.Text,
+			Label:   s.Name,
+		})
+	}
+	buf, err := yaml.Marshal(mf)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(file, buf, fileMode); err != nil {
+		return err
+	}
+	return os.Chtimes(file, modTime, modTime)
+}
+
+// groupModTime returns the most recent Snippet.ModTime in group, or the
+// zero time if group has no snippets.
+func groupModTime(group *expander.Group) time.Time {
+	var modTime time.Time
+	for _, s := range group.Snippets {
+		if s.ModTime.After(modTime) {
+			modTime = s.ModTime
+		}
+	}
+	return modTime
+}
+
+// matchPath returns the on-disk match file path for the given flattened
+// group name.
+func matchPath(dir, name string) string {
+	return path.Join(dir, strings.ReplaceAll(name, "/", "-")+matchExt)
+}
+
+// loadGroup returns a new *expander.Group parsed from the given match file.
+func loadGroup(file string) (*expander.Group, error) {
+	fi, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	mf := &matchFile{}
+	if err := yaml.Unmarshal(buf, mf); err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(file), matchExt)
+	g := &expander.Group{Name: name}
+	for _, m := range mf.Matches {
+		label := m.Label
+		if label == "" {
+			label = m.Trigger
+		}
+		s := &expander.Snippet{
+			Name:    label,
+			Abbr:    m.Trigger,
+			Text:    m.Replace,
+			Parent:  g,
+			ModTime: fi.ModTime(),
+		}
+		g.Snippets = append(g.Snippets, s)
+	}
+	return g, nil
+}
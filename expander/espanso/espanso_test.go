@@ -0,0 +1,60 @@
+package espanso
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	fixture, err := ioutil.ReadFile("testdata/greetings.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "greetings.yml"), fixture, fileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	e := &Espanso{dir: dir, groups: make(map[string]rawGroup)}
+	if err := e.Load(); err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	want := e.Groups()
+	for _, g := range want {
+		// Mutate and bump ModTime past the fixture file's mtime, otherwise
+		// writeGroup's up-to-date check makes Write() a no-op and this test
+		// would never exercise the YAML marshalling it claims to cover.
+		for _, s := range g.Snippets {
+			s.Text = "Howdy, World!"
+			s.ModTime = s.ModTime.Add(24 * time.Hour)
+		}
+		e.SetGroup(g)
+	}
+	if err := e.Write(); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+
+	got := &Espanso{dir: dir, groups: make(map[string]rawGroup)}
+	if err := got.Load(); err != nil {
+		t.Fatalf("reload Load() = %v", err)
+	}
+	gotGroups := got.Groups()
+	if len(gotGroups) != len(want) {
+		t.Fatalf("len(Groups()) = %v, want %v", len(gotGroups), len(want))
+	}
+	g, gg := want[0], gotGroups[0]
+	if g.Name != gg.Name || len(g.Snippets) != len(gg.Snippets) {
+		t.Fatalf("group = %+v, want %+v", gg, g)
+	}
+	for i, s := range g.Snippets {
+		gs := gg.Snippets[i]
+		if gs.Abbr != s.Abbr || gs.Text != s.Text || gs.Name != s.Name {
+			t.Errorf("snippet %v = %+v, want %+v", i, gs, s)
+		}
+		if gs.Text != "Howdy, World!" {
+			t.Errorf("snippet %v Text = %q, want %q (Write() never serialized)", i, gs.Text, "Howdy, World!")
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"path"
 	"sync"
 	"time"
+
+	"github.com/golang/glog"
 )
 
 var (
@@ -43,21 +45,26 @@ func (g Group) Path() string {
 	return path.Join(g.Parent.Path(), g.Name)
 }
 
-// Merge recursively merges the given Group's children groups and snippets.
-func (g *Group) Merge(other *Group) {
-	g.MergeAll(other.Groups)
-	g.mergeSnippets(other.Snippets)
+// Merge recursively merges the given Group's children groups and snippets,
+// resolving any Snippet name collisions per policy.
+func (g *Group) Merge(other *Group, policy ConflictPolicy) error {
+	if err := g.MergeAll(other.Groups, policy); err != nil {
+		return err
+	}
+	return g.mergeSnippets(other.Snippets, policy)
 }
 
 // MergeAll recursively merges the given list of groups to the corresponding child Group entry,
 // creates a new child Group entry if it does not already exist.
-func (g *Group) MergeAll(groups []*Group) {
+func (g *Group) MergeAll(groups []*Group, policy ConflictPolicy) error {
 	for _, right := range groups {
 		found := false
 		for _, left := range g.Groups {
 			if left.Name == right.Name {
 				found = true
-				left.Merge(right)
+				if err := left.Merge(right, policy); err != nil {
+					return err
+				}
 				break
 			}
 		}
@@ -65,23 +72,39 @@ func (g *Group) MergeAll(groups []*Group) {
 			g.Groups = append(g.Groups, right)
 		}
 	}
+	return nil
 }
 
-// mergeSnippets upserts the given snippets.
-func (g *Group) mergeSnippets(snippets []*Snippet) {
-	for _, snippet := range snippets {
-		found := false
+// mergeSnippets upserts the given snippets, resolving any Name collision
+// with the existing Snippet via policy.
+func (g *Group) mergeSnippets(snippets []*Snippet, policy ConflictPolicy) error {
+	for _, right := range snippets {
+		idx := -1
 		for i, s := range g.Snippets {
-			if snippet.Name == s.Name {
-				found = true
-				g.Snippets[i] = snippet
+			if right.Name == s.Name {
+				idx = i
 				break
 			}
 		}
-		if !found {
-			g.Snippets = append(g.Snippets, snippet)
+		if idx < 0 {
+			g.Snippets = append(g.Snippets, right)
+			continue
+		}
+		left := g.Snippets[idx]
+		glog.Infof("conflict: %v vs %v", left.Path(), right.Path())
+		resolved, err := policy.Resolve(left, right)
+		if err != nil {
+			return err
 		}
+		if resolved.Name != left.Name {
+			// The policy renamed the incoming Snippet out of collision
+			// (e.g. RenamePolicy); keep both rather than overwriting.
+			g.Snippets = append(g.Snippets, resolved)
+			continue
+		}
+		g.Snippets[idx] = resolved
 	}
+	return nil
 }
 
 func (g Group) String() string {
@@ -106,6 +129,157 @@ func (s Snippet) String() string {
 	return s.Name
 }
 
+// ConflictPolicy resolves a merge conflict between two Snippets sharing the
+// same Name, returning the Snippet that should occupy that slot. If the
+// returned Snippet's Name differs from left's, it is kept alongside left
+// rather than replacing it.
+type ConflictPolicy interface {
+	Resolve(left, right *Snippet) (*Snippet, error)
+}
+
+// OverwritePolicy always keeps the incoming Snippet, the historical default
+// merge behavior.
+type OverwritePolicy struct{}
+
+// Resolve implements ConflictPolicy.
+func (OverwritePolicy) Resolve(left, right *Snippet) (*Snippet, error) {
+	return right, nil
+}
+
+// KeepExistingPolicy always keeps the existing Snippet, discarding the
+// incoming one.
+type KeepExistingPolicy struct{}
+
+// Resolve implements ConflictPolicy.
+func (KeepExistingPolicy) Resolve(left, right *Snippet) (*Snippet, error) {
+	return left, nil
+}
+
+// NewestWinsPolicy keeps whichever Snippet has the more recent ModTime.
+type NewestWinsPolicy struct{}
+
+// Resolve implements ConflictPolicy.
+func (NewestWinsPolicy) Resolve(left, right *Snippet) (*Snippet, error) {
+	if right.ModTime.After(left.ModTime) {
+		return right, nil
+	}
+	return left, nil
+}
+
+// RenamePolicy keeps both Snippets, appending Suffix to the incoming one's
+// Name so it no longer collides. An empty Suffix defaults to " (imported)".
+type RenamePolicy struct {
+	Suffix string
+}
+
+// Resolve implements ConflictPolicy.
+func (p RenamePolicy) Resolve(left, right *Snippet) (*Snippet, error) {
+	suffix := p.Suffix
+	if suffix == "" {
+		suffix = " (imported)"
+	}
+	renamed := *right
+	renamed.Name = right.Name + suffix
+	// right.Parent still points into the source tree; reparent to left's
+	// Group so Path() (and anything that logs it, e.g. AbbrConflicts)
+	// reports the renamed Snippet's actual location once merged in.
+	renamed.Parent = left.Parent
+	return &renamed, nil
+}
+
+// FailOnConflictPolicy aborts the merge on any collision.
+type FailOnConflictPolicy struct{}
+
+// Resolve implements ConflictPolicy.
+func (FailOnConflictPolicy) Resolve(left, right *Snippet) (*Snippet, error) {
+	return nil, fmt.Errorf("conflicting snippets: %v", left.Path())
+}
+
+// AbbrConflict is a pair of Snippets sharing the same Abbr.
+type AbbrConflict struct {
+	Left, Right *Snippet
+}
+
+// AbbrConflicts returns one AbbrConflict for every Snippet in the Group tree
+// whose non-empty Abbr was already used by an earlier Snippet, pairing it
+// with the first Snippet that used that Abbr, since duplicate abbreviations
+// silently break expansion once written out. Later duplicates are not paired
+// with each other.
+func (g *Group) AbbrConflicts() []AbbrConflict {
+	byAbbr := make(map[string]*Snippet)
+	var conflicts []AbbrConflict
+	g.walkAbbrs(byAbbr, &conflicts)
+	return conflicts
+}
+
+// walkAbbrs recursively collects Snippets by Abbr, recording a conflict each
+// time an already-seen Abbr is seen again.
+func (g *Group) walkAbbrs(byAbbr map[string]*Snippet, conflicts *[]AbbrConflict) {
+	for _, s := range g.Snippets {
+		if s.Abbr == "" {
+			continue
+		}
+		if existing, ok := byAbbr[s.Abbr]; ok {
+			*conflicts = append(*conflicts, AbbrConflict{Left: existing, Right: s})
+			continue
+		}
+		byAbbr[s.Abbr] = s
+	}
+	for _, child := range g.Groups {
+		child.walkAbbrs(byAbbr, conflicts)
+	}
+}
+
+// ChangeType describes the kind of filesystem mutation a Change plans.
+type ChangeType int
+
+// Valid ChangeType values.
+const (
+	Create ChangeType = iota
+	Update
+	Delete
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case Create:
+		return "+"
+	case Update:
+		return "~"
+	case Delete:
+		return "-"
+	default:
+		return "?"
+	}
+}
+
+// Change describes a single planned group directory or snippet file
+// mutation, as returned by DryRunner.Plan.
+type Change struct {
+	Type ChangeType
+	// Kind is "group" or "snippet".
+	Kind                   string
+	Path                   string
+	OldSize, NewSize       int64
+	OldModTime, NewModTime time.Time
+}
+
+func (c Change) String() string {
+	if c.Type != Update {
+		return fmt.Sprintf("%v %v %v", c.Type, c.Kind, c.Path)
+	}
+	return fmt.Sprintf("%v %v %v (mtime %v → %v, %v → %v bytes)",
+		c.Type, c.Kind, c.Path,
+		c.OldModTime.Format("2006-01-02"), c.NewModTime.Format("2006-01-02"),
+		c.OldSize, c.NewSize)
+}
+
+// DryRunner is implemented by Expanders that can preview the filesystem
+// changes Write would make without performing them.
+type DryRunner interface {
+	Plan() ([]Change, error)
+}
+
 // NewExpander is a function to create a new Expander.
 type NewExpander func() Expander
 
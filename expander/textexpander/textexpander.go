@@ -2,16 +2,17 @@
 package textexpander
 
 import (
-	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path"
+	"sort"
 	"sync"
 	"time"
 
 	plist "github.com/DHowett/go-plist"
+	"github.com/google/uuid"
 	"github.com/hazaelsan/spandex/expander"
 )
 
@@ -21,6 +22,13 @@ func init() {
 	}
 }
 
+const fileMode os.FileMode = 0644
+
+// snippetNamespace namespaces UUIDv5 generation for snippets, so that a
+// given Snippet.Path() always derives the same UUID across runs instead of
+// minting a new one (and thus a duplicate entry) every time.
+var snippetNamespace = uuid.MustParse("32525f52-1172-45f5-bb14-b8442f721249")
+
 // Command line flags.
 var (
 	teFile = flag.String("te_file", path.Join(os.Getenv("HOME"), "Dropbox/TextExpander/Settings.textexpander"), "TextExpander settings file")
@@ -107,9 +115,136 @@ func (te *TextExpander) SetGroup(group *expander.Group) {
 	te.groups = append(te.groups, group)
 }
 
-// Write is not implemented yet.
+// Write saves all current Groups to disk, merging with any existing
+// settings file rather than overwriting it outright.
 func (te *TextExpander) Write() error {
-	return errors.New("not implemented")
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	existing, err := loadFile(te.file)
+	if err != nil {
+		return err
+	}
+	bySnippet := make(map[string]rawSnippet)
+	for _, s := range existing.Snippets {
+		bySnippet[s.UUID] = s
+	}
+	byGroup := make(map[string]rawGroup)
+	for _, g := range existing.Groups {
+		byGroup[g.Name] = g
+	}
+
+	for _, root := range te.groups {
+		for _, fg := range flatten(root, "") {
+			rg := rawGroup{Name: fg.name}
+			for _, s := range fg.group.Snippets {
+				rs := mergeSnippet(s, bySnippet)
+				bySnippet[rs.UUID] = rs
+				rg.UUIDs = append(rg.UUIDs, rs.UUID)
+			}
+			byGroup[rg.Name] = rg
+		}
+	}
+
+	data := &rawData{}
+	for _, s := range bySnippet {
+		data.Snippets = append(data.Snippets, s)
+	}
+	for _, g := range byGroup {
+		data.Groups = append(data.Groups, g)
+	}
+	// Sort for deterministic output: Write ranges over maps above, and an
+	// unordered Settings.textexpander causes spurious Dropbox sync churn
+	// and diffs on every run even when nothing actually changed.
+	sort.Slice(data.Snippets, func(i, j int) bool {
+		return data.Snippets[i].UUID < data.Snippets[j].UUID
+	})
+	sort.Slice(data.Groups, func(i, j int) bool {
+		return data.Groups[i].Name < data.Groups[j].Name
+	})
+	te.data = data
+	return writeFile(te.file, data)
+}
+
+// flatGroup pairs a flattened (ancestor-joined) name with its source Group.
+type flatGroup struct {
+	name  string
+	group *expander.Group
+}
+
+// flatten recursively flattens a Group tree into TextExpander's flat group
+// model, joining ancestor names since TE has no concept of nested groups.
+func flatten(g *expander.Group, prefix string) []flatGroup {
+	name := g.Name
+	if prefix != "" {
+		name = path.Join(prefix, g.Name)
+	}
+	out := []flatGroup{{name: name, group: g}}
+	for _, child := range g.Groups {
+		out = append(out, flatten(child, name)...)
+	}
+	return out
+}
+
+// mergeSnippet returns the rawSnippet for s, preserving its origin UUID if
+// s.Name is one (i.e. s came from Load(), see parse()), or else deriving a
+// stable UUID from its Path() so repeated runs of a newly imported snippet
+// don't mint a new UUID (and thus a duplicate entry) every time. The
+// on-disk entry is kept as-is if it's already up to date, mirroring the
+// ModTime check in autokey.AutoKey.writeSnippet.
+func mergeSnippet(s *expander.Snippet, existing map[string]rawSnippet) rawSnippet {
+	id := s.Name
+	fromLoad := false
+	if _, err := uuid.Parse(id); err == nil {
+		fromLoad = true
+	} else {
+		id = uuid.NewSHA1(snippetNamespace, []byte(s.Path())).String()
+	}
+	old, ok := existing[id]
+	if ok && !old.ModDate.Before(s.ModTime) {
+		return old
+	}
+	// expander.Snippet has no Label field; for a Snippet that came from
+	// Load(), s.Name is its own UUID (see parse()), not a human label, so
+	// reuse the on-disk Label rather than overwriting it with the UUID.
+	label := s.Name
+	if fromLoad {
+		label = old.Label
+	}
+	return rawSnippet{
+		Abbr:    s.Abbr,
+		Label:   label,
+		Text:    s.Text,
+		UUID:    id,
+		ModDate: s.ModTime,
+	}
+}
+
+// loadFile returns the rawData stored in file, or an empty rawData if file
+// does not exist.
+func loadFile(file string) (*rawData, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return &rawData{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data := &rawData{}
+	if err := plist.NewDecoder(f).Decode(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// writeFile writes data to file as a plist.
+func writeFile(file string, data *rawData) error {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fileMode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return plist.NewEncoder(f).Encode(data)
 }
 
 // parse loads all groups and snippets from raw plist data.
@@ -125,10 +260,10 @@ func (te *TextExpander) parse() error {
 	}
 	for _, g := range te.data.Groups {
 		group := &expander.Group{Name: g.Name}
-		for _, uuid := range g.UUIDs {
-			s, ok := snippets[uuid]
+		for _, id := range g.UUIDs {
+			s, ok := snippets[id]
 			if !ok {
-				return fmt.Errorf("invalid snippet UUID: %v", uuid)
+				return fmt.Errorf("invalid snippet UUID: %v", id)
 			}
 			s.Parent = group
 			group.Snippets = append(group.Snippets, s)
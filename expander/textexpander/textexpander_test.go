@@ -0,0 +1,131 @@
+package textexpander
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hazaelsan/spandex/expander"
+)
+
+func TestWriteRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "Settings.textexpander")
+	fixture, err := ioutil.ReadFile("testdata/fixture.textexpander")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(file, fixture, fileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	te := &TextExpander{file: file}
+	if err := te.Load(); err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	want := te.Groups()
+	if err := te.Write(); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+
+	got := &TextExpander{file: file}
+	if err := got.Load(); err != nil {
+		t.Fatalf("reload Load() = %v", err)
+	}
+	gotGroups := got.Groups()
+	if len(gotGroups) != len(want) {
+		t.Fatalf("len(Groups()) = %v, want %v", len(gotGroups), len(want))
+	}
+	for i, g := range want {
+		gg := gotGroups[i]
+		if gg.Name != g.Name || len(gg.Snippets) != len(g.Snippets) {
+			t.Fatalf("group %v = %+v, want %+v", i, gg, g)
+		}
+		for j, s := range g.Snippets {
+			gs := gg.Snippets[j]
+			if gs.Abbr != s.Abbr || gs.Text != s.Text || !gs.ModTime.Equal(s.ModTime) {
+				t.Errorf("snippet %v = %+v, want %+v", j, gs, s)
+			}
+		}
+	}
+}
+
+func TestWriteEditPreservesLabel(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "Settings.textexpander")
+	fixture, err := ioutil.ReadFile("testdata/fixture.textexpander")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(file, fixture, fileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	te := &TextExpander{file: file}
+	if err := te.Load(); err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	snippet := te.Groups()[0].Snippets[0]
+	snippet.Text = "Howdy!"
+	snippet.ModTime = snippet.ModTime.Add(24 * time.Hour)
+	if err := te.Write(); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+
+	data, err := loadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Snippets) != 1 {
+		t.Fatalf("len(Snippets) = %v, want 1", len(data.Snippets))
+	}
+	got := data.Snippets[0]
+	if got.Text != "Howdy!" {
+		t.Errorf("Text = %q, want %q", got.Text, "Howdy!")
+	}
+	if got.Label != "Hi there" {
+		t.Errorf("Label = %q, want %q (must not be overwritten with the snippet's UUID)", got.Label, "Hi there")
+	}
+}
+
+func TestWriteStableUUID(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "Settings.textexpander")
+	group := &expander.Group{Name: "Imported"}
+	snippet := &expander.Snippet{
+		Name:    "Hello",
+		Abbr:    "hw",
+		Text:    "Hello, World!",
+		Parent:  group,
+		ModTime: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+	}
+	group.Snippets = append(group.Snippets, snippet)
+
+	te := &TextExpander{file: file}
+	te.SetGroup(group)
+	if err := te.Write(); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	first, err := loadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Snippets) != 1 {
+		t.Fatalf("len(Snippets) = %v, want 1", len(first.Snippets))
+	}
+
+	if err := te.Write(); err != nil {
+		t.Fatalf("second Write() = %v", err)
+	}
+	second, err := loadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Snippets) != 1 {
+		t.Fatalf("len(Snippets) after repeated Write() = %v, want 1 (duplicate snippet created)", len(second.Snippets))
+	}
+	if first.Snippets[0].UUID != second.Snippets[0].UUID {
+		t.Errorf("UUID changed across Write() calls: %v != %v", first.Snippets[0].UUID, second.Snippets[0].UUID)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/hazaelsan/spandex/expander"
 	_ "github.com/hazaelsan/spandex/expander/autokey"
+	_ "github.com/hazaelsan/spandex/expander/espanso"
 	_ "github.com/hazaelsan/spandex/expander/textexpander"
 )
 
@@ -15,8 +16,28 @@ var (
 	srcExp     = flag.String("source", "", "source expander")
 	dstExp     = flag.String("dest", "", "destination expander")
 	importName = flag.String("import_name", "", "group name for imported snippets")
+	conflict   = flag.String("conflict", "overwrite", "conflict resolution policy, one of overwrite, keep, newest, rename, fail")
+	dryRun     = flag.Bool("dry_run", false, "print the planned changes instead of writing them")
 )
 
+// conflictPolicy returns the expander.ConflictPolicy named by -conflict.
+func conflictPolicy(name string) (expander.ConflictPolicy, error) {
+	switch name {
+	case "overwrite":
+		return expander.OverwritePolicy{}, nil
+	case "keep":
+		return expander.KeepExistingPolicy{}, nil
+	case "newest":
+		return expander.NewestWinsPolicy{}, nil
+	case "rename":
+		return expander.RenamePolicy{}, nil
+	case "fail":
+		return expander.FailOnConflictPolicy{}, nil
+	default:
+		return nil, fmt.Errorf("invalid -conflict: %v", name)
+	}
+}
+
 func main() {
 	flag.Parse()
 	if *srcExp == "" || *dstExp == "" {
@@ -25,6 +46,10 @@ func main() {
 	if *importName == "" {
 		*importName = fmt.Sprintf("Imported from %v", *srcExp)
 	}
+	policy, err := conflictPolicy(*conflict)
+	if err != nil {
+		glog.Exit(err)
+	}
 	src, err := expander.New(*srcExp)
 	if err != nil {
 		glog.Exit(err)
@@ -40,8 +65,27 @@ func main() {
 	for _, g := range src.Groups() {
 		g.Parent = root
 	}
-	root.MergeAll(src.Groups())
+	if err := root.MergeAll(src.Groups(), policy); err != nil {
+		glog.Exit(err)
+	}
+	for _, c := range root.AbbrConflicts() {
+		glog.Warningf("duplicate abbreviation %v: %v vs %v", c.Left.Abbr, c.Left.Path(), c.Right.Path())
+	}
 	dst.SetGroup(root)
+	if *dryRun {
+		planner, ok := dst.(expander.DryRunner)
+		if !ok {
+			glog.Exitf("-dest=%v does not support -dry_run", *dstExp)
+		}
+		changes, err := planner.Plan()
+		if err != nil {
+			glog.Exit(err)
+		}
+		for _, c := range changes {
+			fmt.Println(c)
+		}
+		return
+	}
 	if err := dst.Write(); err != nil {
 		glog.Exit(err)
 	}